@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/slack-go/slack"
+
+	"github.com/ahrechushkin/redmine-slack-bot/internal/redmine"
+	"github.com/ahrechushkin/redmine-slack-bot/internal/store"
+)
+
+const (
+	ActionStartWork    = "start_work"
+	ActionLogTime      = "log_time"
+	ActionChangeStatus = "change_status"
+	ActionOpenRedmine  = "open_redmine"
+
+	CallbackLogTimeModal      = "log_time_modal"
+	CallbackChangeStatusModal = "change_status_modal"
+
+	BlockLogTimeHours    = "log_time_hours"
+	BlockLogTimeComments = "log_time_comments"
+	BlockChangeStatus    = "change_status_select"
+)
+
+func handleInteractionEvent(ctx context.Context, interaction slack.InteractionCallback, client *slack.Client, redmineClient *redmine.Client, userStore *store.Store) error {
+	switch interaction.Type {
+	case slack.InteractionTypeBlockActions:
+		for _, action := range interaction.ActionCallback.BlockActions {
+			if err := dispatchBlockAction(ctx, action, interaction, client, redmineClient, userStore); err != nil {
+				return err
+			}
+		}
+	case slack.InteractionTypeViewSubmission:
+		return dispatchViewSubmission(ctx, interaction, client, redmineClient)
+	}
+
+	return nil
+}
+
+func dispatchBlockAction(ctx context.Context, action *slack.BlockAction, interaction slack.InteractionCallback, client *slack.Client, redmineClient *redmine.Client, userStore *store.Store) error {
+	switch action.ActionID {
+	case ActionStartWork:
+		return startWork(ctx, action.Value, redmineClient)
+	case ActionLogTime:
+		return openLogTimeModal(action.Value, interaction.TriggerID, client)
+	case ActionChangeStatus:
+		return openChangeStatusModal(ctx, action.Value, interaction.TriggerID, client, redmineClient)
+	case ActionLinkConfirm:
+		return confirmLink(interaction.User.ID, interaction.Channel.ID, action.Value, client, userStore)
+	}
+
+	return nil
+}
+
+func startWork(ctx context.Context, issueId string, redmineClient *redmine.Client) error {
+	statusId, err := findStatusIdByName(ctx, redmineClient, "In Progress")
+	if err != nil {
+		return err
+	}
+
+	return updateIssueStatus(ctx, redmineClient, issueId, statusId)
+}
+
+func openLogTimeModal(issueId, triggerId string, client *slack.Client) error {
+	modal := slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		CallbackID:      CallbackLogTimeModal,
+		PrivateMetadata: issueId,
+		Title:           slack.NewTextBlockObject(slack.PlainTextType, fmt.Sprintf("Log time on #%s", issueId), false, false),
+		Submit:          slack.NewTextBlockObject(slack.PlainTextType, "Log", false, false),
+		Close:           slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false),
+		Blocks: slack.Blocks{
+			BlockSet: []slack.Block{
+				slack.NewInputBlock(
+					BlockLogTimeHours,
+					slack.NewTextBlockObject(slack.PlainTextType, "Hours", false, false),
+					nil,
+					slack.NewPlainTextInputBlockElement(slack.NewTextBlockObject(slack.PlainTextType, "e.g. 1.5", false, false), BlockLogTimeHours),
+				),
+				slack.NewInputBlock(
+					BlockLogTimeComments,
+					slack.NewTextBlockObject(slack.PlainTextType, "Comment", false, false),
+					nil,
+					slack.NewPlainTextInputBlockElement(slack.NewTextBlockObject(slack.PlainTextType, "What did you work on?", false, false), BlockLogTimeComments),
+				),
+			},
+		},
+	}
+
+	_, err := client.OpenView(triggerId, modal)
+	return err
+}
+
+func openChangeStatusModal(ctx context.Context, issueId, triggerId string, client *slack.Client, redmineClient *redmine.Client) error {
+	statuses, err := redmineClient.IssueStatuses(ctx)
+	if err != nil {
+		return err
+	}
+
+	options := make([]*slack.OptionBlockObject, 0, len(statuses))
+	for ndx := range statuses {
+		options = append(options, slack.NewOptionBlockObject(
+			strconv.Itoa(statuses[ndx].Id),
+			slack.NewTextBlockObject(slack.PlainTextType, statuses[ndx].Name, false, false),
+			nil,
+		))
+	}
+
+	modal := slack.ModalViewRequest{
+		Type:            slack.VTModal,
+		CallbackID:      CallbackChangeStatusModal,
+		PrivateMetadata: issueId,
+		Title:           slack.NewTextBlockObject(slack.PlainTextType, fmt.Sprintf("Change status of #%s", issueId), false, false),
+		Submit:          slack.NewTextBlockObject(slack.PlainTextType, "Update", false, false),
+		Close:           slack.NewTextBlockObject(slack.PlainTextType, "Cancel", false, false),
+		Blocks: slack.Blocks{
+			BlockSet: []slack.Block{
+				slack.NewInputBlock(
+					BlockChangeStatus,
+					slack.NewTextBlockObject(slack.PlainTextType, "Status", false, false),
+					nil,
+					slack.NewOptionsSelectBlockElement(slack.OptTypeStatic, slack.NewTextBlockObject(slack.PlainTextType, "Select a status", false, false), BlockChangeStatus, options...),
+				),
+			},
+		},
+	}
+
+	_, err = client.OpenView(triggerId, modal)
+	return err
+}
+
+func dispatchViewSubmission(ctx context.Context, interaction slack.InteractionCallback, client *slack.Client, redmineClient *redmine.Client) error {
+	switch interaction.View.CallbackID {
+	case CallbackLogTimeModal:
+		return submitLogTime(ctx, interaction, client, redmineClient)
+	case CallbackChangeStatusModal:
+		return submitChangeStatus(ctx, interaction, redmineClient)
+	}
+
+	return nil
+}
+
+func submitLogTime(ctx context.Context, interaction slack.InteractionCallback, client *slack.Client, redmineClient *redmine.Client) error {
+	issueId, err := strconv.Atoi(interaction.View.PrivateMetadata)
+	if err != nil {
+		return fmt.Errorf("failed to parse issue id: %w", err)
+	}
+
+	values := interaction.View.State.Values
+	hours := values[BlockLogTimeHours][BlockLogTimeHours].Value
+	comments := values[BlockLogTimeComments][BlockLogTimeComments].Value
+
+	if _, err := strconv.ParseFloat(hours, 64); err != nil {
+		_, _, postErr := client.PostMessage(interaction.User.ID, slack.MsgOptionText(
+			fmt.Sprintf("Couldn't log time on #%d: %q isn't a valid number of hours.", issueId, hours), false,
+		))
+		return postErr
+	}
+
+	if err := redmineClient.CreateTimeEntry(ctx, redmine.TimeEntryInput{
+		IssueID:  issueId,
+		Hours:    hours,
+		Comments: comments,
+	}); err != nil {
+		var apiErr *redmine.APIError
+		if errors.As(err, &apiErr) {
+			_, _, postErr := client.PostMessage(interaction.User.ID, slack.MsgOptionText(
+				fmt.Sprintf("Couldn't log time on #%d: %s", issueId, apiErr), false,
+			))
+			return postErr
+		}
+		return err
+	}
+
+	return nil
+}
+
+func submitChangeStatus(ctx context.Context, interaction slack.InteractionCallback, redmineClient *redmine.Client) error {
+	selected := interaction.View.State.Values[BlockChangeStatus][BlockChangeStatus].SelectedOption.Value
+
+	statusId, err := strconv.Atoi(selected)
+	if err != nil {
+		return fmt.Errorf("failed to parse selected status: %w", err)
+	}
+
+	return updateIssueStatus(ctx, redmineClient, interaction.View.PrivateMetadata, statusId)
+}
+
+func updateIssueStatus(ctx context.Context, redmineClient *redmine.Client, issueId string, statusId int) error {
+	id, err := strconv.Atoi(issueId)
+	if err != nil {
+		return fmt.Errorf("failed to parse issue id: %w", err)
+	}
+
+	return redmineClient.UpdateIssue(ctx, id, redmine.IssuePatch{StatusID: &statusId})
+}
+
+func findStatusIdByName(ctx context.Context, redmineClient *redmine.Client, name string) (int, error) {
+	statuses, err := redmineClient.IssueStatuses(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for ndx := range statuses {
+		if statuses[ndx].Name == name {
+			return statuses[ndx].Id, nil
+		}
+	}
+
+	return 0, fmt.Errorf("status %q not found", name)
+}
@@ -0,0 +1,254 @@
+package redmine
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	pageLimit       = 100
+	maxRetries      = 5
+	retryBaseDelay  = 500 * time.Millisecond
+	retryMaxDelay   = 30 * time.Second
+	retryBackoffFac = 2
+)
+
+// Client is a small Redmine REST API client. It follows pagination
+// transparently, retries 429/5xx responses with exponential backoff, and
+// propagates context cancellation into in-flight requests.
+type Client struct {
+	baseURL    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+func NewClient(baseURL, apiKey string) *Client {
+	return &Client{
+		baseURL:    baseURL,
+		apiKey:     apiKey,
+		httpClient: &http.Client{},
+	}
+}
+
+func (c *Client) Users(ctx context.Context) ([]User, error) {
+	var all []User
+	err := c.fetchAll(ctx, "users.json", "users", nil, func(raw json.RawMessage) error {
+		var page []User
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		all = append(all, page...)
+		return nil
+	})
+	return all, err
+}
+
+func (c *Client) Issues(ctx context.Context, filter IssueFilter) ([]Issue, error) {
+	var all []Issue
+	err := c.fetchAll(ctx, "issues.json", "issues", filter.values(), func(raw json.RawMessage) error {
+		var page []Issue
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		all = append(all, page...)
+		return nil
+	})
+	return all, err
+}
+
+func (c *Client) TimeEntries(ctx context.Context, filter TimeEntryFilter) ([]TimeEntry, error) {
+	var all []TimeEntry
+	err := c.fetchAll(ctx, "time_entries.json", "time_entries", filter.values(), func(raw json.RawMessage) error {
+		var page []TimeEntry
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		all = append(all, page...)
+		return nil
+	})
+	return all, err
+}
+
+func (c *Client) IssueStatuses(ctx context.Context) ([]IssueStatus, error) {
+	var all []IssueStatus
+	err := c.fetchAll(ctx, "issue_statuses.json", "issue_statuses", nil, func(raw json.RawMessage) error {
+		var page []IssueStatus
+		if err := json.Unmarshal(raw, &page); err != nil {
+			return err
+		}
+		all = append(all, page...)
+		return nil
+	})
+	return all, err
+}
+
+func (c *Client) UpdateIssue(ctx context.Context, id int, patch IssuePatch) error {
+	_, err := c.do(ctx, http.MethodPut, fmt.Sprintf("issues/%d.json", id), nil, patch.body())
+	return err
+}
+
+func (c *Client) CreateTimeEntry(ctx context.Context, entry TimeEntryInput) error {
+	_, err := c.do(ctx, http.MethodPost, "time_entries.json", nil, entry.body())
+	return err
+}
+
+// fetchAll loops over Redmine's offset/limit pagination envelope until every
+// page has been retrieved or ctx is cancelled, handing each page's raw
+// "<itemsKey>" array to decodePage.
+func (c *Client) fetchAll(ctx context.Context, resource, itemsKey string, params url.Values, decodePage func(json.RawMessage) error) error {
+	offset := 0
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		pageParams := url.Values{}
+		for key, values := range params {
+			for _, value := range values {
+				pageParams.Add(key, value)
+			}
+		}
+		pageParams.Set("offset", strconv.Itoa(offset))
+		pageParams.Set("limit", strconv.Itoa(pageLimit))
+
+		body, err := c.do(ctx, http.MethodGet, resource, pageParams, nil)
+		if err != nil {
+			return err
+		}
+
+		var envelope map[string]json.RawMessage
+		if err := json.Unmarshal(body, &envelope); err != nil {
+			return fmt.Errorf("redmine: decode %s response: %w", resource, err)
+		}
+
+		itemsRaw, ok := envelope[itemsKey]
+		if !ok {
+			return fmt.Errorf("redmine: %s response missing %q", resource, itemsKey)
+		}
+		if err := decodePage(itemsRaw); err != nil {
+			return fmt.Errorf("redmine: decode %s page: %w", resource, err)
+		}
+
+		var meta paginationMeta
+		if err := json.Unmarshal(body, &meta); err != nil {
+			return fmt.Errorf("redmine: decode %s pagination: %w", resource, err)
+		}
+
+		offset = meta.Offset + meta.Limit
+		if meta.Limit == 0 || offset >= meta.TotalCount {
+			return nil
+		}
+	}
+}
+
+// do sends a single Redmine request, retrying 429/5xx responses with
+// exponential backoff and jitter. POST requests are never retried, since a
+// lost response to a successful create would otherwise be resent as a
+// duplicate.
+func (c *Client) do(ctx context.Context, method, resource string, params url.Values, body interface{}) ([]byte, error) {
+	var encodedBody []byte
+	if body != nil {
+		encoded, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("redmine: encode request body: %w", err)
+		}
+		encodedBody = encoded
+	}
+
+	requestURL := fmt.Sprintf("%s/%s", c.baseURL, resource)
+	if len(params) > 0 {
+		requestURL = fmt.Sprintf("%s?%s", requestURL, params.Encode())
+	}
+
+	// POST creates a resource and isn't idempotent: if the response to a
+	// successful create is lost (timeout, reset, 503 after commit), retrying
+	// would silently create a duplicate. Only GET/PUT/DELETE are retried.
+	retryable := method != http.MethodPost
+
+	delay := retryBaseDelay
+
+	for attempt := 0; ; attempt++ {
+		var bodyReader io.Reader
+		if encodedBody != nil {
+			bodyReader = bytes.NewReader(encodedBody)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, requestURL, bodyReader)
+		if err != nil {
+			return nil, fmt.Errorf("redmine: build request: %w", err)
+		}
+		req.Header.Set("X-Redmine-API-Key", c.apiKey)
+		if encodedBody != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+
+		response, err := c.httpClient.Do(req)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			if !retryable || attempt >= maxRetries {
+				return nil, fmt.Errorf("redmine: %s %s: %w", method, resource, err)
+			}
+			if waitErr := sleepWithJitter(ctx, delay); waitErr != nil {
+				return nil, waitErr
+			}
+			delay = nextDelay(delay)
+			continue
+		}
+
+		respBody, readErr := io.ReadAll(response.Body)
+		response.Body.Close()
+		if readErr != nil {
+			return nil, fmt.Errorf("redmine: read response body: %w", readErr)
+		}
+
+		if response.StatusCode == http.StatusTooManyRequests || response.StatusCode >= http.StatusInternalServerError {
+			if !retryable || attempt >= maxRetries {
+				return nil, &APIError{Method: method, Resource: resource, StatusCode: response.StatusCode, Body: string(respBody)}
+			}
+			if waitErr := sleepWithJitter(ctx, delay); waitErr != nil {
+				return nil, waitErr
+			}
+			delay = nextDelay(delay)
+			continue
+		}
+
+		if response.StatusCode >= http.StatusBadRequest {
+			return nil, &APIError{Method: method, Resource: resource, StatusCode: response.StatusCode, Body: string(respBody)}
+		}
+
+		return respBody, nil
+	}
+}
+
+func nextDelay(delay time.Duration) time.Duration {
+	next := delay * retryBackoffFac
+	if next > retryMaxDelay {
+		next = retryMaxDelay
+	}
+	return next
+}
+
+func sleepWithJitter(ctx context.Context, delay time.Duration) error {
+	jittered := time.Duration(rand.Int63n(int64(delay)+1)) + delay/2
+
+	timer := time.NewTimer(jittered)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,67 @@
+package redmine
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestUsersFollowsPaginationUntilTotalCount(t *testing.T) {
+	const total = 250 // spans three pages of pageLimit (100)
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+
+		offset := 0
+		fmt.Sscanf(r.URL.Query().Get("offset"), "%d", &offset)
+
+		limit := total - offset
+		if limit > pageLimit {
+			limit = pageLimit
+		}
+
+		fmt.Fprintf(w, `{"total_count": %d, "offset": %d, "limit": %d, "users": [`, total, offset, limit)
+		for i := 0; i < limit; i++ {
+			if i > 0 {
+				fmt.Fprint(w, ",")
+			}
+			fmt.Fprintf(w, `{"id": %d, "login": "user%d"}`, offset+i, offset+i)
+		}
+		fmt.Fprint(w, "]}")
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+
+	users, err := client.Users(context.Background())
+	if err != nil {
+		t.Fatalf("Users() returned error: %v", err)
+	}
+
+	if len(users) != total {
+		t.Fatalf("got %d users, want %d", len(users), total)
+	}
+	if requests != 3 {
+		t.Fatalf("got %d requests, want 3 (one per page)", requests)
+	}
+}
+
+func TestUsersStopsOnEmptyPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"total_count": 0, "offset": 0, "limit": 0, "users": []}`)
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "test-key")
+
+	users, err := client.Users(context.Background())
+	if err != nil {
+		t.Fatalf("Users() returned error: %v", err)
+	}
+	if len(users) != 0 {
+		t.Fatalf("got %d users, want 0", len(users))
+	}
+}
@@ -0,0 +1,16 @@
+package redmine
+
+import "fmt"
+
+// APIError is returned when Redmine responds with a non-2xx status that
+// retries could not recover from.
+type APIError struct {
+	Method     string
+	Resource   string
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("redmine: %s %s: unexpected status %d: %s", e.Method, e.Resource, e.StatusCode, e.Body)
+}
@@ -0,0 +1,137 @@
+package redmine
+
+import (
+	"net/url"
+	"strconv"
+)
+
+// paginationMeta mirrors the offset/limit envelope Redmine wraps every
+// collection response in.
+type paginationMeta struct {
+	TotalCount int `json:"total_count"`
+	Offset     int `json:"offset"`
+	Limit      int `json:"limit"`
+}
+
+type User struct {
+	Id    int    `json:"id"`
+	Login string `json:"login"`
+	Mail  string `json:"mail"`
+}
+
+type Issue struct {
+	Id      int    `json:"id"`
+	Subject string `json:"subject"`
+	Project struct {
+		Id   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"project"`
+	Status struct {
+		Id   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"status"`
+	Priority struct {
+		Id   int    `json:"id"`
+		Name string `json:"name"`
+	} `json:"priority"`
+	EstimatedHours float32 `json:"estimated_hours"`
+	SpentHours     float32 `json:"spent_hours"`
+	DueDate        string  `json:"due_date"`
+}
+
+type TimeEntry struct {
+	Id    int `json:"id"`
+	Issue struct {
+		Id int `json:"id"`
+	} `json:"issue"`
+	Hours    float32 `json:"hours"`
+	Comments string  `json:"comments"`
+	SpentOn  string  `json:"spent_on"`
+}
+
+type IssueStatus struct {
+	Id   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+// IssueFilter narrows an Issues() call. Zero-value fields are omitted from
+// the request.
+type IssueFilter struct {
+	AssignedToID int
+	StatusID     string
+	ProjectID    string
+}
+
+func (f IssueFilter) values() url.Values {
+	values := url.Values{}
+	if f.AssignedToID != 0 {
+		values.Set("assigned_to_id", strconv.Itoa(f.AssignedToID))
+	}
+	if f.StatusID != "" {
+		values.Set("status_id", f.StatusID)
+	}
+	if f.ProjectID != "" {
+		values.Set("project_id", f.ProjectID)
+	}
+	return values
+}
+
+// TimeEntryFilter narrows a TimeEntries() call. Zero-value fields are
+// omitted from the request. SpentOn matches a single exact date; SpentOnFrom
+// and SpentOnBefore together match an inclusive date range and are sent
+// through Redmine's advanced filter syntax (spent_on only matches exact
+// dates as a plain query parameter).
+type TimeEntryFilter struct {
+	UserID        int
+	SpentOn       string
+	SpentOnAfter  string
+	SpentOnBefore string
+}
+
+func (f TimeEntryFilter) values() url.Values {
+	values := url.Values{}
+	if f.UserID != 0 {
+		values.Set("user_id", strconv.Itoa(f.UserID))
+	}
+	if f.SpentOn != "" {
+		values.Set("spent_on", f.SpentOn)
+	}
+	if f.SpentOnAfter != "" && f.SpentOnBefore != "" {
+		values.Add("f[]", "spent_on")
+		values.Set("op[spent_on]", "><")
+		values.Add("v[spent_on][]", f.SpentOnAfter)
+		values.Add("v[spent_on][]", f.SpentOnBefore)
+	}
+	return values
+}
+
+// IssuePatch describes a partial update to an issue. Nil fields are left
+// untouched.
+type IssuePatch struct {
+	StatusID *int
+}
+
+func (p IssuePatch) body() map[string]interface{} {
+	issue := map[string]interface{}{}
+	if p.StatusID != nil {
+		issue["status_id"] = *p.StatusID
+	}
+	return map[string]interface{}{"issue": issue}
+}
+
+// TimeEntryInput describes a new time entry to log against an issue.
+type TimeEntryInput struct {
+	IssueID  int
+	Hours    string
+	Comments string
+}
+
+func (t TimeEntryInput) body() map[string]interface{} {
+	return map[string]interface{}{
+		"time_entry": map[string]interface{}{
+			"issue_id": t.IssueID,
+			"hours":    t.Hours,
+			"comments": t.Comments,
+		},
+	}
+}
@@ -0,0 +1,94 @@
+// Package store persists the mapping between Slack user IDs and Redmine
+// user IDs, so commands no longer have to assume a Slack username matches a
+// Redmine login.
+package store
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// Store is a JSON-file-backed map of Slack user ID to Redmine user ID.
+type Store struct {
+	path string
+
+	mu   sync.Mutex
+	data map[string]int
+}
+
+func New(path string) (*Store, error) {
+	store := &Store{
+		path: path,
+		data: map[string]int{},
+	}
+
+	if err := store.load(); err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func (s *Store) Get(slackUserId string) (int, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	redmineUserId, ok := s.data[slackUserId]
+	return redmineUserId, ok
+}
+
+func (s *Store) Set(slackUserId string, redmineUserId int) error {
+	s.mu.Lock()
+	s.data[slackUserId] = redmineUserId
+	s.mu.Unlock()
+
+	return s.save()
+}
+
+func (s *Store) Delete(slackUserId string) error {
+	s.mu.Lock()
+	delete(s.data, slackUserId)
+	s.mu.Unlock()
+
+	return s.save()
+}
+
+func (s *Store) List() map[string]int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list := make(map[string]int, len(s.data))
+	for slackUserId, redmineUserId := range s.data {
+		list[slackUserId] = redmineUserId
+	}
+
+	return list
+}
+
+func (s *Store) load() error {
+	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return json.Unmarshal(data, &s.data)
+}
+
+func (s *Store) save() error {
+	s.mu.Lock()
+	data, err := json.Marshal(s.data)
+	s.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(s.path, data, 0644)
+}
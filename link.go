@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/slack-go/slack"
+
+	"github.com/ahrechushkin/redmine-slack-bot/internal/redmine"
+	"github.com/ahrechushkin/redmine-slack-bot/internal/store"
+)
+
+const ActionLinkConfirm = "link_confirm"
+
+func handleLinkCommand(ctx context.Context, command slack.SlashCommand, client *slack.Client, redmineClient *redmine.Client) error {
+	query := strings.TrimSpace(command.Text)
+	if query == "" {
+		_, _, err := client.PostMessage(command.ChannelID, slack.MsgOptionText("Usage: /link <redmine-login-or-email>", false))
+		return err
+	}
+
+	users, err := redmineClient.Users(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch users: %w", err)
+	}
+
+	for ndx := range users {
+		if users[ndx].Login == query || users[ndx].Mail == query {
+			return postLinkConfirmation(command.ChannelID, command.UserID, users[ndx], client)
+		}
+	}
+
+	_, _, err = client.PostMessage(command.ChannelID, slack.MsgOptionText(fmt.Sprintf("No Redmine user found matching %q", query), false))
+	return err
+}
+
+// linkConfirmValue encodes the Redmine user being linked together with the
+// Slack user who requested the link, so confirmLink can refuse to bind the
+// account to whoever happens to click the button.
+func linkConfirmValue(redmineUserId int, requesterId string) string {
+	return fmt.Sprintf("%d:%s", redmineUserId, requesterId)
+}
+
+func postLinkConfirmation(channelID, requesterId string, user redmine.User, client *slack.Client) error {
+	_, err := client.PostEphemeral(channelID, requesterId, slack.MsgOptionBlocks(
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("Found Redmine user *%s* (%s). Link this account?", user.Login, user.Mail), false, false),
+			nil, nil,
+		),
+		slack.NewActionBlock(
+			"link_confirmation",
+			slack.NewButtonBlockElement(ActionLinkConfirm, linkConfirmValue(user.Id, requesterId), slack.NewTextBlockObject(slack.PlainTextType, "Yes, that's me", false, false)),
+		),
+	))
+	return err
+}
+
+func handleUnlinkCommand(command slack.SlashCommand, client *slack.Client, userStore *store.Store) error {
+	if err := userStore.Delete(command.UserID); err != nil {
+		return fmt.Errorf("failed to remove link: %w", err)
+	}
+
+	_, _, err := client.PostMessage(command.ChannelID, slack.MsgOptionText(fmt.Sprintf("<@%s> is no longer linked to a Redmine account", command.UserName), false))
+	return err
+}
+
+func handleWhoamiCommand(ctx context.Context, command slack.SlashCommand, client *slack.Client, redmineClient *redmine.Client, userStore *store.Store) error {
+	redmineUserId, ok := userStore.Get(command.UserID)
+	if !ok {
+		_, _, err := client.PostMessage(command.ChannelID, slack.MsgOptionText(fmt.Sprintf("<@%s> isn't linked yet. Use /link <redmine-login-or-email>", command.UserName), false))
+		return err
+	}
+
+	users, err := redmineClient.Users(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to fetch users: %w", err)
+	}
+
+	for ndx := range users {
+		if users[ndx].Id == redmineUserId {
+			_, _, err := client.PostMessage(command.ChannelID, slack.MsgOptionText(fmt.Sprintf("<@%s> is linked to Redmine user *%s* (%s)", command.UserName, users[ndx].Login, users[ndx].Mail), false))
+			return err
+		}
+	}
+
+	_, _, err = client.PostMessage(command.ChannelID, slack.MsgOptionText(fmt.Sprintf("<@%s> is linked to a Redmine user that no longer exists", command.UserName), false))
+	return err
+}
+
+func confirmLink(clickerId, channelID, value string, client *slack.Client, userStore *store.Store) error {
+	redmineUserIdRaw, requesterId, ok := strings.Cut(value, ":")
+	if !ok {
+		return fmt.Errorf("malformed link confirmation value %q", value)
+	}
+
+	if clickerId != requesterId {
+		_, err := client.PostEphemeral(channelID, clickerId, slack.MsgOptionText("This confirmation isn't for you.", false))
+		return err
+	}
+
+	redmineUserId, err := strconv.Atoi(redmineUserIdRaw)
+	if err != nil {
+		return fmt.Errorf("failed to parse redmine user id: %w", err)
+	}
+
+	if err := userStore.Set(requesterId, redmineUserId); err != nil {
+		return fmt.Errorf("failed to persist link: %w", err)
+	}
+
+	_, _, postErr := client.PostMessage(channelID, slack.MsgOptionText(fmt.Sprintf("<@%s> linked!", requesterId), false))
+	return postErr
+}
+
+// resolveUserId resolves the Redmine user id for the Slack user invoking
+// command, preferring the link store and falling back to matching the
+// Redmine login against the Slack username.
+func resolveUserId(ctx context.Context, command slack.SlashCommand, redmineClient *redmine.Client, userStore *store.Store) (int, error) {
+	if redmineUserId, ok := userStore.Get(command.UserID); ok {
+		return redmineUserId, nil
+	}
+
+	users, err := redmineClient.Users(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to fetch users: %w", err)
+	}
+
+	for ndx := range users {
+		if users[ndx].Login == command.UserName {
+			return users[ndx].Id, nil
+		}
+	}
+
+	return 0, fmt.Errorf("no redmine user found for slack user %q; try /link <redmine-login-or-email>", command.UserName)
+}
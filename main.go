@@ -1,60 +1,41 @@
 package main
 
 import (
-	"fmt"
-	"os"
-	"log"
 	"context"
 	"errors"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
-	"net/http"
-	"encoding/json"
-	"strconv"
 
 	"github.com/joho/godotenv"
 	"github.com/slack-go/slack"
-	"github.com/slack-go/slack/socketmode"
 	"github.com/slack-go/slack/slackevents"
-)
-
-type UsersList struct {
-	Users []User `json:"users"`
-}
+	"github.com/slack-go/slack/socketmode"
 
-type User struct {
-	Id int `json:"id"`
-	Login string `json:"login"`
-	Mail  string `json:"mail"`
-}
+	"github.com/ahrechushkin/redmine-slack-bot/internal/redmine"
+	"github.com/ahrechushkin/redmine-slack-bot/internal/store"
+)
 
-type IssuesList struct {
-	Issues []Issue `json:"issues"`
-}
-
-type Issue struct {
-	Id int `json:"id"`
-	Subject string `json:"subject"`
-	Project struct {
-		Id int `json:"id"`
-		Name string `json:"name"`
-	} `json:"project"`
-	Status struct {
-		Id int `json:"id"`
-		Name string `json:"name"`
-	} `json:"status"`
-	EstimatedHours float32 `json:"estimated_hours"`
-	SpentHours float32 `json:"spent_hours"`
-}
+const userStorePath = "user_links.json"
 
 func main() {
 	godotenv.Load(".env")
-	
+
 	token := os.Getenv("SLACK_AUTH_TOKEN")
 	appToken := os.Getenv("SLACK_APP_TOKEN")
 	debug := os.Getenv("BOT_DEBUG_MODE") == "true"
 
 	client := slack.New(token, slack.OptionDebug(debug), slack.OptionAppLevelToken(appToken))
+	redmineClient := redmine.NewClient(os.Getenv("REDMINE_URL"), os.Getenv("REDMINE_API_TOKEN"))
+
+	userStore, err := store.New(userStorePath)
+	if err != nil {
+		log.Fatalf("failed to open user link store: %v", err)
+	}
 
 	socketClient := socketmode.New(
 		client,
@@ -66,7 +47,10 @@ func main() {
 
 	defer cancel()
 
-	go func(ctx context.Context, client *slack.Client, socketClient *socketmode.Client) {
+	scheduler := NewScheduler(client, redmineClient)
+	go scheduler.Run(ctx)
+
+	go func(ctx context.Context, client *slack.Client, redmineClient *redmine.Client, userStore *store.Store, socketClient *socketmode.Client) {
 		for {
 			select {
 			case <-ctx.Done():
@@ -81,7 +65,7 @@ func main() {
 						continue
 					}
 					socketClient.Ack(*event.Request)
-					
+
 					err := handleEventMessage(eventsAPIEvent, client)
 
 					if err != nil {
@@ -96,15 +80,34 @@ func main() {
 
 					socketClient.Ack(*event.Request)
 
-					_, err := handleSlashCommand(command, client)
+					_, err := handleSlashCommand(ctx, command, client, redmineClient, userStore)
 					if err != nil {
-						log.Fatal(err)
+						log.Printf("failed to handle slash command %q: %v", command.Command, err)
+						if _, postErr := client.PostEphemeral(command.ChannelID, command.UserID, slack.MsgOptionText(
+							slashCommandErrorMessage(err), false,
+						)); postErr != nil {
+							log.Printf("failed to post error message: %v", postErr)
+						}
+					}
+				case socketmode.EventTypeInteractive:
+					interaction, ok := event.Data.(slack.InteractionCallback)
+
+					if !ok {
+						log.Printf("Could not type cast the event to the InteractionCallback: %v\n", event)
+						continue
+					}
+
+					socketClient.Ack(*event.Request)
+
+					err := handleInteractionEvent(ctx, interaction, client, redmineClient, userStore)
+					if err != nil {
+						log.Printf("failed to handle interaction: %v", err)
 					}
 				}
 
 			}
 		}
-	}(ctx, client, socketClient)
+	}(ctx, client, redmineClient, userStore, socketClient)
 
 	socketClient.Run()
 }
@@ -134,7 +137,7 @@ func handleAppMentionEvent(event *slackevents.AppMentionEvent, client *slack.Cli
 		attachment.Text = fmt.Sprintf("How can I help you @%s?", user.Name)
 		attachment.Color = "#3d3d3d"
 	}
-	
+
 	_, _, err = client.PostMessage(event.Channel, slack.MsgOptionAttachments(attachment))
 	if err != nil {
 		return fmt.Errorf("failed to post message: %w", err)
@@ -162,64 +165,229 @@ func handleEventMessage(event slackevents.EventsAPIEvent, client *slack.Client)
 	return nil
 }
 
-func handleSlashCommand(command slack.SlashCommand, client *slack.Client) (interface{}, error) {
+func handleSlashCommand(ctx context.Context, command slack.SlashCommand, client *slack.Client, redmineClient *redmine.Client, userStore *store.Store) (interface{}, error) {
 	switch command.Command {
 	case "/help":
 		return nil, handleHelpCommand(command, client)
 	case "/issues":
-		return nil, handleIssuesCommand(command, client)
+		return nil, handleIssuesCommand(ctx, command, client, redmineClient, userStore)
 	case "/active-issues":
-		return nil, handleActiveIssuesCommand(command, client)
+		return nil, handleActiveIssuesCommand(ctx, command, client, redmineClient, userStore)
 	case "/daily-report":
-		return nil, handleDailyReportCommand(command, client)
+		return nil, handleDailyReportCommand(ctx, command, client, redmineClient, userStore)
+	case "/link":
+		return nil, handleLinkCommand(ctx, command, client, redmineClient)
+	case "/unlink":
+		return nil, handleUnlinkCommand(command, client, userStore)
+	case "/whoami":
+		return nil, handleWhoamiCommand(ctx, command, client, redmineClient, userStore)
 	default:
 		return nil, handleUnexistingCommand(command, client)
 	}
 }
 
-func handleIssuesCommand(command slack.SlashCommand, client *slack.Client) (error) {
-	username := command.UserName
-	users := usersList()
-
-	userId := 0
-
-	for ndx := range users {
-		if users[ndx].Login == username {
-			userId = users[ndx].Id
-		}
+// slashCommandErrorMessage turns a slash-command handler error into a
+// message safe to show the invoking user. Redmine API errors are reported
+// by status code only, since their body may echo back request details we
+// don't want to expose in a channel.
+func slashCommandErrorMessage(err error) string {
+	var apiErr *redmine.APIError
+	if errors.As(err, &apiErr) {
+		return fmt.Sprintf("Redmine returned an error (status %d). Please try again later.", apiErr.StatusCode)
 	}
+	return fmt.Sprintf("Sorry, something went wrong: %v", err)
+}
 
-	issues := usersIssues(userId)
-	issuesTxt := ""
+func handleIssuesCommand(ctx context.Context, command slack.SlashCommand, client *slack.Client, redmineClient *redmine.Client, userStore *store.Store) error {
+	userId, err := resolveUserId(ctx, command, redmineClient, userStore)
+	if err != nil {
+		return err
+	}
 
-	for ndx := range issues {
-		link := generateLink(strconv.Itoa(issues[ndx].Id))
-		issuesTxt = issuesTxt + fmt.Sprintf("<%s|#%s: %s> (%.1fh/%.1fh) \n", link, strconv.Itoa(issues[ndx].Id), issues[ndx].Subject, issues[ndx].EstimatedHours, issues[ndx].SpentHours)
+	issues, err := redmineClient.Issues(ctx, redmine.IssueFilter{AssignedToID: userId})
+	if err != nil {
+		return fmt.Errorf("failed to fetch issues: %w", err)
 	}
 
-	message := fmt.Sprintf("Issues assigned to <@%s> \n-----------\n%s", command.UserName, issuesTxt)
-	_, _, err := client.PostMessage(command.ChannelID, slack.MsgOptionText(message, false))
-	
+	_, _, err = client.PostMessage(command.ChannelID, slack.MsgOptionText(fmt.Sprintf("Issues assigned to <@%s>", command.UserName), false))
 	if err != nil {
 		return fmt.Errorf("failed to post message: %w", err)
 	}
+
+	for ndx := range issues {
+		_, _, err := client.PostMessage(command.ChannelID, slack.MsgOptionBlocks(issueBlocks(issues[ndx])...))
+		if err != nil {
+			return fmt.Errorf("failed to post message: %w", err)
+		}
+	}
+
 	return nil
 }
 
+func issueBlocks(issue redmine.Issue) []slack.Block {
+	link := generateLink(strconv.Itoa(issue.Id))
+
+	return []slack.Block{
+		slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, fmt.Sprintf("<%s|#%d: %s> (%.1fh/%.1fh)", link, issue.Id, issue.Subject, issue.SpentHours, issue.EstimatedHours), false, false),
+			nil, nil,
+		),
+		slack.NewActionBlock(
+			strconv.Itoa(issue.Id),
+			slack.NewButtonBlockElement(ActionStartWork, strconv.Itoa(issue.Id), slack.NewTextBlockObject(slack.PlainTextType, "Start work", false, false)),
+			slack.NewButtonBlockElement(ActionLogTime, strconv.Itoa(issue.Id), slack.NewTextBlockObject(slack.PlainTextType, "Log time", false, false)),
+			slack.NewButtonBlockElement(ActionChangeStatus, strconv.Itoa(issue.Id), slack.NewTextBlockObject(slack.PlainTextType, "Change status", false, false)),
+			slack.NewButtonBlockElement(ActionOpenRedmine, strconv.Itoa(issue.Id), slack.NewTextBlockObject(slack.PlainTextType, "Open in Redmine", false, false)).WithURL(link),
+		),
+	}
+}
+
 func generateLink(id string) string {
 	redmineUrl := os.Getenv("REDMINE_URL")
 
 	return fmt.Sprintf("%s/issues/%s", redmineUrl, id)
 }
 
-func handleActiveIssuesCommand(command slack.SlashCommand, client *slack.Client) (error) {
+func handleActiveIssuesCommand(ctx context.Context, command slack.SlashCommand, client *slack.Client, redmineClient *redmine.Client, userStore *store.Store) error {
+	userId, err := resolveUserId(ctx, command, redmineClient, userStore)
+	if err != nil {
+		return err
+	}
+
+	filter := redmine.IssueFilter{StatusID: "open", AssignedToID: userId}
+
+	projectText := strings.TrimSpace(command.Text)
+	if projectText != "" {
+		filter.ProjectID = projectText
+	}
+
+	issues, err := redmineClient.Issues(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to fetch active issues: %w", err)
+	}
+
+	if len(issues) == 0 {
+		_, _, err := client.PostMessage(command.ChannelID, slack.MsgOptionText(fmt.Sprintf("No active issues for <@%s>", command.UserName), false))
+		return err
+	}
+
+	today := time.Now().Format("2006-01-02")
+	attachments := make([]slack.Attachment, 0, len(issues))
+
+	for ndx := range issues {
+		issue := issues[ndx]
+		link := generateLink(strconv.Itoa(issue.Id))
+
+		overdue := issue.DueDate != "" && issue.DueDate < today
+		color := "#4af030"
+		if overdue {
+			color = "#ff0000"
+		} else if issue.Priority.Name == "High" || issue.Priority.Name == "Urgent" || issue.Priority.Name == "Immediate" {
+			color = "#ffae42"
+		}
+
+		dueDate := issue.DueDate
+		if dueDate == "" {
+			dueDate = "-"
+		}
+
+		attachments = append(attachments, slack.Attachment{
+			Color:     color,
+			Title:     fmt.Sprintf("#%d: %s", issue.Id, issue.Subject),
+			TitleLink: link,
+			Fields: []slack.AttachmentField{
+				{Title: "Project", Value: issue.Project.Name, Short: true},
+				{Title: "Status", Value: issue.Status.Name, Short: true},
+				{Title: "Priority", Value: issue.Priority.Name, Short: true},
+				{Title: "Due Date", Value: dueDate, Short: true},
+				{Title: "Hours", Value: fmt.Sprintf("%.1fh / %.1fh", issue.SpentHours, issue.EstimatedHours), Short: true},
+			},
+		})
+	}
+
+	_, _, err = client.PostMessage(command.ChannelID, slack.MsgOptionText(fmt.Sprintf("Active issues for <@%s>", command.UserName), false), slack.MsgOptionAttachments(attachments...))
+
+	if err != nil {
+		return fmt.Errorf("failed to post message: %w", err)
+	}
 	return nil
 }
 
-func handleDailyReportCommand(command slack.SlashCommand, client *slack.Client) (error) {
+func handleDailyReportCommand(ctx context.Context, command slack.SlashCommand, client *slack.Client, redmineClient *redmine.Client, userStore *store.Store) error {
+	userId, err := resolveUserId(ctx, command, redmineClient, userStore)
+	if err != nil {
+		return err
+	}
+
+	label, filter := dailyReportRange(command.Text)
+	filter.UserID = userId
+
+	timeEntries, err := redmineClient.TimeEntries(ctx, filter)
+	if err != nil {
+		return fmt.Errorf("failed to fetch time entries: %w", err)
+	}
+
+	hoursByIssue := map[int]float32{}
+	issueIds := []int{}
+	var total float32
+
+	for ndx := range timeEntries {
+		entry := timeEntries[ndx]
+		if _, ok := hoursByIssue[entry.Issue.Id]; !ok {
+			issueIds = append(issueIds, entry.Issue.Id)
+		}
+		hoursByIssue[entry.Issue.Id] += entry.Hours
+		total += entry.Hours
+	}
+
+	sort.Ints(issueIds)
+
+	attachment := slack.Attachment{}
+	attachment.Fields = make([]slack.AttachmentField, 0, len(issueIds)+1)
+
+	for _, issueId := range issueIds {
+		link := generateLink(strconv.Itoa(issueId))
+		attachment.Fields = append(attachment.Fields, slack.AttachmentField{
+			Title: fmt.Sprintf("<%s|#%d>", link, issueId),
+			Value: fmt.Sprintf("%.1fh", hoursByIssue[issueId]),
+			Short: true,
+		})
+	}
+
+	attachment.Fields = append(attachment.Fields, slack.AttachmentField{
+		Title: "Total",
+		Value: fmt.Sprintf("%.1fh", total),
+	})
+	attachment.Color = "#3d3d3d"
+	attachment.Text = fmt.Sprintf("Time report for <@%s> (%s)", command.UserName, label)
+
+	_, _, err = client.PostMessage(command.ChannelID, slack.MsgOptionAttachments(attachment))
+
+	if err != nil {
+		return fmt.Errorf("failed to post message: %w", err)
+	}
 	return nil
 }
-func handleHelpCommand(command slack.SlashCommand, client *slack.Client) (error) {
+
+// dailyReportRange translates the /daily-report argument into a label and
+// the TimeEntryFilter date bounds that produce it. "week" spans an inclusive
+// range and is sent through Redmine's advanced filter syntax, since
+// spent_on as a plain query parameter only matches a single exact date.
+func dailyReportRange(arg string) (string, redmine.TimeEntryFilter) {
+	switch strings.TrimSpace(strings.ToLower(arg)) {
+	case "yesterday":
+		return "yesterday", redmine.TimeEntryFilter{SpentOn: time.Now().AddDate(0, 0, -1).Format("2006-01-02")}
+	case "week":
+		return "this week", redmine.TimeEntryFilter{
+			SpentOnAfter:  time.Now().AddDate(0, 0, -7).Format("2006-01-02"),
+			SpentOnBefore: time.Now().Format("2006-01-02"),
+		}
+	default:
+		return "today", redmine.TimeEntryFilter{SpentOn: time.Now().Format("2006-01-02")}
+	}
+}
+
+func handleHelpCommand(command slack.SlashCommand, client *slack.Client) error {
 	attachment := slack.Attachment{}
 
 	attachment.Fields = []slack.AttachmentField{
@@ -234,14 +402,14 @@ func handleHelpCommand(command slack.SlashCommand, client *slack.Client) (error)
 
 	attachment.Text = fmt.Sprintf("Hello! %s\n I can show you all your tickets with command /tickets", command.UserName)
 	_, _, err := client.PostMessage(command.ChannelID, slack.MsgOptionAttachments(attachment))
-	
+
 	if err != nil {
 		return fmt.Errorf("failed to post message: %w", err)
 	}
 	return nil
 }
 
-func handleUnexistingCommand(command slack.SlashCommand, client *slack.Client) (error) {
+func handleUnexistingCommand(command slack.SlashCommand, client *slack.Client) error {
 	attachment := slack.Attachment{}
 
 	attachment.Fields = []slack.AttachmentField{
@@ -256,58 +424,10 @@ func handleUnexistingCommand(command slack.SlashCommand, client *slack.Client) (
 
 	attachment.Text = fmt.Sprintf("Hello! %s\n Sorry, but i can't do that", command.UserName)
 	_, _, err := client.PostMessage(command.ChannelID, slack.MsgOptionAttachments(attachment))
-	
+
 	if err != nil {
 		return fmt.Errorf("failed to post message: %w", err)
 	}
 	return nil
 }
 
-func usersList () []User {
-	usersListRaw, err := callRedmineApi("GET", "users.json")
-
-	if err != nil {
-		panic(err)
-	}
-	var usersList UsersList;
-	err = usersListRaw.Decode(&usersList)
-	
-	if err != nil {
-		panic(err)
-	}
-
-	return usersList.Users
-}
-
-func usersIssues (userId int) []Issue {
-	issuesListRaw, err := callRedmineApi("GET", fmt.Sprintf("issues.json?assigned_to_id=%s", strconv.Itoa(userId)))
-
-	if err != nil {
-		panic(err)
-	}
-
-	var issuesList IssuesList;
-	err = issuesListRaw.Decode(&issuesList)
-
-	if err != nil {
-		panic(err)
-	}
-
-	return issuesList.Issues
-}
-
-func callRedmineApi(method, resource string) (*json.Decoder, error) {
-	client := &http.Client{}
-	url := fmt.Sprintf("%s/%s", os.Getenv("REDMINE_URL"), resource)
-	req, _ := http.NewRequest(method, url, nil)
-	req.Header.Set("X-Redmine-API-Key", os.Getenv("REDMINE_API_TOKEN"))
-	response, err := client.Do(req)
-
-	if err != nil {
-		panic(err)
-	}
-
-	decodedBody := json.NewDecoder(response.Body)
-
-	return decodedBody, err
-}
\ No newline at end of file
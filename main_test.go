@@ -0,0 +1,43 @@
+package main
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDailyReportRange(t *testing.T) {
+	today := time.Now().Format("2006-01-02")
+	yesterday := time.Now().AddDate(0, 0, -1).Format("2006-01-02")
+	weekAgo := time.Now().AddDate(0, 0, -7).Format("2006-01-02")
+
+	tests := []struct {
+		arg         string
+		wantLabel   string
+		wantAfter   string
+		wantBefore  string
+		wantSpentOn string
+	}{
+		{arg: "", wantLabel: "today", wantSpentOn: today},
+		{arg: "today", wantLabel: "today", wantSpentOn: today},
+		{arg: "Yesterday", wantLabel: "yesterday", wantSpentOn: yesterday},
+		{arg: "  week  ", wantLabel: "this week", wantAfter: weekAgo, wantBefore: today},
+		{arg: "WEEK", wantLabel: "this week", wantAfter: weekAgo, wantBefore: today},
+	}
+
+	for _, tt := range tests {
+		label, filter := dailyReportRange(tt.arg)
+
+		if label != tt.wantLabel {
+			t.Errorf("dailyReportRange(%q) label = %q, want %q", tt.arg, label, tt.wantLabel)
+		}
+		if filter.SpentOn != tt.wantSpentOn {
+			t.Errorf("dailyReportRange(%q) SpentOn = %q, want %q", tt.arg, filter.SpentOn, tt.wantSpentOn)
+		}
+		if filter.SpentOnAfter != tt.wantAfter {
+			t.Errorf("dailyReportRange(%q) SpentOnAfter = %q, want %q", tt.arg, filter.SpentOnAfter, tt.wantAfter)
+		}
+		if filter.SpentOnBefore != tt.wantBefore {
+			t.Errorf("dailyReportRange(%q) SpentOnBefore = %q, want %q", tt.arg, filter.SpentOnBefore, tt.wantBefore)
+		}
+	}
+}
@@ -0,0 +1,254 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/slack-go/slack"
+
+	"github.com/ahrechushkin/redmine-slack-bot/internal/redmine"
+)
+
+const defaultDailyReportCron = "09:00"
+const defaultDailyReportTZ = "UTC"
+const schedulerStatePath = "scheduler_state.json"
+const schedulerTickInterval = time.Minute
+
+// Scheduler posts a personalized stand-up digest to every linked Slack user
+// once a day, at the time configured via DAILY_REPORT_CRON/DAILY_REPORT_TZ.
+type Scheduler struct {
+	slackClient   *slack.Client
+	redmineClient *redmine.Client
+
+	timeOfDay string
+	location  *time.Location
+	statePath string
+
+	mu       sync.Mutex
+	lastSent map[string]string
+}
+
+func NewScheduler(slackClient *slack.Client, redmineClient *redmine.Client) *Scheduler {
+	timeOfDay := os.Getenv("DAILY_REPORT_CRON")
+	if timeOfDay == "" {
+		timeOfDay = defaultDailyReportCron
+	}
+
+	tz := os.Getenv("DAILY_REPORT_TZ")
+	if tz == "" {
+		tz = defaultDailyReportTZ
+	}
+
+	location, err := time.LoadLocation(tz)
+	if err != nil {
+		log.Printf("scheduler: invalid DAILY_REPORT_TZ %q, falling back to UTC: %v", tz, err)
+		location = time.UTC
+	}
+
+	scheduler := &Scheduler{
+		slackClient:   slackClient,
+		redmineClient: redmineClient,
+		timeOfDay:     timeOfDay,
+		location:      location,
+		statePath:     schedulerStatePath,
+		lastSent:      map[string]string{},
+	}
+
+	scheduler.loadState()
+
+	return scheduler
+}
+
+// Run blocks until ctx is cancelled, checking once a minute whether it's
+// time to send the daily digest.
+func (s *Scheduler) Run(ctx context.Context) {
+	ticker := time.NewTicker(schedulerTickInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.maybeSendDigest(ctx, now)
+		}
+	}
+}
+
+func (s *Scheduler) maybeSendDigest(ctx context.Context, now time.Time) {
+	localNow := now.In(s.location)
+	if localNow.Format("15:04") != s.timeOfDay {
+		return
+	}
+
+	today := localNow.Format("2006-01-02")
+
+	users, err := s.redmineClient.Users(ctx)
+	if err != nil {
+		log.Printf("scheduler: failed to fetch redmine users: %v", err)
+		return
+	}
+
+	for ndx := range users {
+		user := users[ndx]
+
+		slackUser, err := s.slackClient.GetUserByEmail(user.Mail)
+		if err != nil {
+			continue
+		}
+
+		if s.alreadySentToday(slackUser.ID, today) {
+			continue
+		}
+
+		if err := s.sendDigest(ctx, slackUser.ID, user, today); err != nil {
+			log.Printf("scheduler: failed to send digest to %s: %v", slackUser.ID, err)
+			continue
+		}
+
+		s.markSent(slackUser.ID, today)
+	}
+}
+
+func (s *Scheduler) sendDigest(ctx context.Context, slackUserId string, user redmine.User, today string) error {
+	channel, _, _, err := s.slackClient.OpenConversation(&slack.OpenConversationParameters{
+		Users: []string{slackUserId},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to open conversation: %w", err)
+	}
+
+	yesterday := time.Now().In(s.location).AddDate(0, 0, -1).Format("2006-01-02")
+
+	timeEntries, err := s.redmineClient.TimeEntries(ctx, redmine.TimeEntryFilter{UserID: user.Id, SpentOn: yesterday})
+	if err != nil {
+		return fmt.Errorf("failed to fetch time entries: %w", err)
+	}
+
+	issues, err := s.redmineClient.Issues(ctx, redmine.IssueFilter{StatusID: "open", AssignedToID: user.Id})
+	if err != nil {
+		return fmt.Errorf("failed to fetch open issues: %w", err)
+	}
+
+	attachments := []slack.Attachment{timeEntriesAttachment(timeEntries), openIssuesAttachment(issues, today)}
+
+	_, _, err = s.slackClient.PostMessage(channel.ID, slack.MsgOptionText("Good morning! Here's your stand-up digest.", false), slack.MsgOptionAttachments(attachments...))
+	if err != nil {
+		return fmt.Errorf("failed to post digest: %w", err)
+	}
+
+	return nil
+}
+
+func timeEntriesAttachment(timeEntries []redmine.TimeEntry) slack.Attachment {
+	hoursByIssue := map[int]float32{}
+	issueIds := []int{}
+	var total float32
+
+	for ndx := range timeEntries {
+		entry := timeEntries[ndx]
+		if _, ok := hoursByIssue[entry.Issue.Id]; !ok {
+			issueIds = append(issueIds, entry.Issue.Id)
+		}
+		hoursByIssue[entry.Issue.Id] += entry.Hours
+		total += entry.Hours
+	}
+
+	fields := make([]slack.AttachmentField, 0, len(issueIds)+1)
+	for _, issueId := range issueIds {
+		link := generateLink(strconv.Itoa(issueId))
+		fields = append(fields, slack.AttachmentField{
+			Title: fmt.Sprintf("<%s|#%d>", link, issueId),
+			Value: fmt.Sprintf("%.1fh", hoursByIssue[issueId]),
+			Short: true,
+		})
+	}
+
+	return slack.Attachment{
+		Color:  "#3d3d3d",
+		Title:  fmt.Sprintf("Yesterday (%.1fh total)", total),
+		Fields: fields,
+	}
+}
+
+func openIssuesAttachment(issues []redmine.Issue, today string) slack.Attachment {
+	fields := make([]slack.AttachmentField, 0, len(issues))
+	overdue := false
+
+	for ndx := range issues {
+		issue := issues[ndx]
+		link := generateLink(strconv.Itoa(issue.Id))
+
+		title := fmt.Sprintf("<%s|#%d: %s>", link, issue.Id, issue.Subject)
+		if issue.DueDate != "" && issue.DueDate < today {
+			title = fmt.Sprintf(":red_circle: %s (overdue %s)", title, issue.DueDate)
+			overdue = true
+		}
+
+		fields = append(fields, slack.AttachmentField{
+			Title: title,
+			Value: fmt.Sprintf("%.1fh / %.1fh", issue.SpentHours, issue.EstimatedHours),
+		})
+	}
+
+	color := "#4af030"
+	if overdue {
+		color = "#ff0000"
+	}
+
+	return slack.Attachment{
+		Color:  color,
+		Title:  "Open issues",
+		Fields: fields,
+	}
+}
+
+func (s *Scheduler) alreadySentToday(slackUserId, today string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lastSent[slackUserId] == today
+}
+
+func (s *Scheduler) markSent(slackUserId, today string) {
+	s.mu.Lock()
+	s.lastSent[slackUserId] = today
+	s.mu.Unlock()
+
+	s.saveState()
+}
+
+func (s *Scheduler) loadState() {
+	data, err := os.ReadFile(s.statePath)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := json.Unmarshal(data, &s.lastSent); err != nil {
+		log.Printf("scheduler: failed to parse state file %s: %v", s.statePath, err)
+	}
+}
+
+func (s *Scheduler) saveState() {
+	s.mu.Lock()
+	data, err := json.Marshal(s.lastSent)
+	s.mu.Unlock()
+
+	if err != nil {
+		log.Printf("scheduler: failed to encode state: %v", err)
+		return
+	}
+
+	if err := os.WriteFile(s.statePath, data, 0644); err != nil {
+		log.Printf("scheduler: failed to persist state file %s: %v", s.statePath, err)
+	}
+}